@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/imdario/mergo"
+)
+
+// SubStateSpec describes an additional DSF to be executed alongside the
+// current one via the top-level `helmfiles` stanza, each with its own
+// selectors, values, and environment. This lets a root DSF compose
+// per-team DSFs without the user scripting multiple `helmsman -f`
+// invocations.
+type SubStateSpec struct {
+	Path        string   `yaml:"path"`
+	Selectors   []string `yaml:"selectors"`
+	Values      []string `yaml:"values"`
+	Environment string   `yaml:"environment"`
+}
+
+// resolveBases recursively loads the DSFs listed in s.Bases and deep-merges
+// them underneath the current state, with the current state's own fields
+// taking precedence over anything it inherits. basePath is used to resolve
+// relative base paths. seen is the same map threaded through every level of
+// the recursion (by loadFile, which marks basePath itself on entry) so a
+// cycle anywhere in the chain -- not just a direct self-reference -- is
+// caught instead of recursing until the stack overflows.
+func (s *state) resolveBases(basePath string, seen map[string]bool) error {
+	for _, b := range s.Bases {
+		path := b
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(basePath), path)
+		}
+
+		base := new(state)
+		// bases carry no environment or values of their own: --environment
+		// and --state-values-* are resolved once, by fromFile, against the
+		// fully-merged state.
+		if ok, msg := base.loadFile(path, nil, nil, seen); !ok {
+			return fmt.Errorf("failed to load base [ %s ]: %s", path, msg)
+		}
+
+		if err := mergo.Merge(s, base); err != nil {
+			return fmt.Errorf("failed to merge base [ %s ]: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// loadHelmfiles loads the additional DSFs referenced by the `helmfiles`
+// stanza into their own states -- each with its own --environment
+// selection (sub.Environment, when set, temporarily overrides the global
+// --environment for the duration of that sub-DSF's load) and its own
+// values files -- applies each one's own selectors on top of the root
+// run's own --target/--group selection, and returns them so the caller can
+// combine them with the root state into a single combined plan.
+func (s *state) loadHelmfiles() ([]*state, error) {
+	subStates := make([]*state, 0, len(s.Helmfiles))
+	for _, sub := range s.Helmfiles {
+		subState := new(state)
+
+		restore := flags.environment
+		if sub.Environment != "" {
+			flags.environment = sub.Environment
+		}
+		ok, msg := subState.fromFile(sub.Path)
+		flags.environment = restore
+
+		if !ok {
+			return nil, fmt.Errorf("failed to load sub-helmfile [ %s ]: %s", sub.Path, msg)
+		}
+
+		if subState.Values == nil {
+			subState.Values = map[string]interface{}{}
+		}
+		for _, f := range sub.Values {
+			values, err := readValuesFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("sub-helmfile [ %s ] values file [ %s ]: %w", sub.Path, f, err)
+			}
+			mergeValues(subState.Values, values)
+		}
+
+		// the root run's own --target/--group selection applies to every
+		// helmfiles sub-DSF too, in addition to that sub-DSF's own
+		// selectors -- otherwise selecting a target on the root run has no
+		// effect on anything loaded via helmfiles.
+		targets := append(append([]string{}, []string(flags.targets)...), sub.Selectors...)
+		subState.makeTargetMap([]string(flags.groups), targets)
+		subState.disableUntargettedApps()
+		subStates = append(subStates, subState)
+	}
+	return subStates, nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadHelmfilesPropagatesRootTargets(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub.yaml")
+	content := "apps:\n" +
+		"  a1:\n" +
+		"    chart: stable/a1\n" +
+		"    version: \"1.0.0\"\n" +
+		"  a2:\n" +
+		"    chart: stable/a2\n" +
+		"    version: \"1.0.0\"\n"
+	if err := os.WriteFile(sub, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	restore := flags.targets
+	flags.targets = stringSliceFlag{"a1"}
+	defer func() { flags.targets = restore }()
+
+	root := &state{Helmfiles: []SubStateSpec{{Path: sub}}}
+	subStates, err := root.loadHelmfiles()
+	if err != nil {
+		t.Fatalf("loadHelmfiles() error = %v", err)
+	}
+	if len(subStates) != 1 {
+		t.Fatalf("loadHelmfiles() returned %d sub-states, want 1", len(subStates))
+	}
+
+	sub0 := subStates[0]
+	if !sub0.Apps["a1"].isConsideredToRun() {
+		t.Errorf("a1 should be considered to run -- it matches the root run's --target")
+	}
+	if sub0.Apps["a2"].isConsideredToRun() {
+		t.Errorf("a2 should have been disabled -- it doesn't match the root run's --target")
+	}
+}
+
+func TestResolveBasesDetectsIndirectCycle(t *testing.T) {
+	dir := t.TempDir()
+
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+
+	if err := os.WriteFile(a, []byte("bases: [\"b.yaml\"]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("bases: [\"a.yaml\"]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := new(state)
+	ok, msg := s.fromFile(a)
+	if ok {
+		t.Fatalf("fromFile() on an A -> B -> A cycle unexpectedly succeeded")
+	}
+	if want := "cycle detected"; !strings.Contains(msg, want) {
+		t.Errorf("fromFile() error = %q, want it to contain %q", msg, want)
+	}
+}
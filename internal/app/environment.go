@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/imdario/mergo"
+	"gopkg.in/yaml.v2"
+)
+
+// environment represents a named entry in the top-level `environments`
+// stanza, mirroring Helmfile's model so a single DSF can declare
+// per-environment default values, values files, secrets, and kubeContext
+// instead of branching DSFs per environment. The active environment is
+// selected with --environment.
+type environment struct {
+	Defaults    map[string]interface{} `yaml:"defaults" toml:"defaults"`
+	Values      []string               `yaml:"values" toml:"values"`
+	Secrets     []string               `yaml:"secrets" toml:"secrets"`
+	KubeContext string                 `yaml:"kubeContext" toml:"kubeContext"`
+}
+
+// selectedEnvironment returns the environment requested via --environment,
+// or nil if no --environment flag was given. It errors if the requested
+// name is not defined in the environments stanza.
+func (s *state) selectedEnvironment() (*environment, error) {
+	if flags.environment == "" {
+		return nil, nil
+	}
+	env, ok := s.Environments[flags.environment]
+	if !ok {
+		return nil, fmt.Errorf("environment [ %s ] is not defined in the environments stanza", flags.environment)
+	}
+	return env, nil
+}
+
+// resolveEnvironment merges the active environment's default values, its
+// values files, and the values already present in s.Values (the DSF's own
+// values stanza) -- in that precedence order -- and applies the environment's
+// kubeContext when the DSF itself did not set one. It is a no-op when
+// --environment was not passed.
+func (s *state) resolveEnvironment() error {
+	env, err := s.selectedEnvironment()
+	if err != nil {
+		return err
+	}
+	if env == nil {
+		return nil
+	}
+
+	merged := map[string]interface{}{}
+	mergeValues(merged, env.Defaults)
+
+	for _, f := range env.Values {
+		values, err := readValuesFile(f)
+		if err != nil {
+			return fmt.Errorf("environment [ %s ] values file [ %s ]: %w", flags.environment, f, err)
+		}
+		mergeValues(merged, values)
+	}
+
+	mergeValues(merged, s.Values)
+	s.Values = merged
+
+	if env.KubeContext != "" && s.Settings.KubeContext == "" {
+		s.Settings.KubeContext = env.KubeContext
+	}
+
+	return nil
+}
+
+// readValuesFile reads a YAML values file from disk into a generic map,
+// rendering it through the same template engine as the DSF itself (unless
+// --skip-template is set) -- this is the renderTemplate reuse the
+// templating request called for, since every values file in the codebase
+// (environment values, --state-values-file, sub-helmfile values) is read
+// through this one function.
+func readValuesFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !flags.skipTemplate {
+		rendered, err := renderTemplate(path, data, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		data = rendered
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// mergeValues deep-merges src into dst using mergo's override semantics, so
+// src wins on conflicts and slices are replaced rather than appended.
+// Existing sibling keys in nested maps are preserved -- merging "a.b.c" and
+// then "a.b.d" yields {a:{b:{c:..,d:..}}}, not a clobbered "b".
+func mergeValues(dst, src map[string]interface{}) {
+	if err := mergo.Merge(&dst, src, mergo.WithOverride); err != nil {
+		log.Error(fmt.Sprintf("failed to merge values: %v", err))
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeValuesPreservesSiblingKeys(t *testing.T) {
+	dst := map[string]interface{}{}
+	mergeValues(dst, map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": "x"}},
+	})
+	mergeValues(dst, map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"d": "y"}},
+	})
+
+	want := map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": "x", "d": "y"}},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("mergeValues() = %#v, want %#v", dst, want)
+	}
+}
+
+func TestMergeValuesSrcOverridesDst(t *testing.T) {
+	dst := map[string]interface{}{"a": "old"}
+	mergeValues(dst, map[string]interface{}{"a": "new"})
+
+	if dst["a"] != "new" {
+		t.Errorf("mergeValues() did not let src override dst, got %#v", dst["a"])
+	}
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EventName identifies a point in the release lifecycle at which hooks can
+// fire, generalizing the untyped globalHooks map into helmfile-style named
+// events.
+type EventName string
+
+const (
+	EventPrepare       EventName = "prepare"
+	EventCleanup       EventName = "cleanup"
+	EventPreSync       EventName = "presync"
+	EventPostSync      EventName = "postsync"
+	EventPreApply      EventName = "preapply"
+	EventPostApply     EventName = "postapply"
+	EventPreUninstall  EventName = "preuninstall"
+	EventPostUninstall EventName = "postuninstall"
+)
+
+// hookSpec describes a single lifecycle hook command.
+type hookSpec struct {
+	Command         string            `yaml:"command"`
+	Args            []string          `yaml:"args"`
+	ShowLogs        bool              `yaml:"showlogs"`
+	Silent          bool              `yaml:"silent"`
+	Env             map[string]string `yaml:"env"`
+	WorkingDir      string            `yaml:"workingDir"`
+	When            string            `yaml:"when"`
+	ContinueOnError bool              `yaml:"continueOnError"`
+}
+
+// events is the typed, ordered hook model keyed by lifecycle event name,
+// superseding the untyped globalHooks map.
+type events map[EventName][]hookSpec
+
+// legacyHookEventNames maps the pre-existing globalHooks keys onto the new
+// typed event names, so DSFs written before the Events model keep working
+// unchanged.
+var legacyHookEventNames = map[string]EventName{
+	"preInstall":  EventPreSync,
+	"postInstall": EventPostSync,
+	"preUpgrade":  EventPreSync,
+	"postUpgrade": EventPostSync,
+	"preDelete":   EventPreUninstall,
+	"postDelete":  EventPostUninstall,
+}
+
+// normalizeEvents maps the legacy, untyped globalHooks shape onto the typed
+// Events model, appending to (rather than replacing) any hooks already
+// declared under `events` for the same event name.
+func (s *state) normalizeEvents() error {
+	if s.Settings.Events == nil {
+		s.Settings.Events = events{}
+	}
+	for legacyName, raw := range s.Settings.GlobalHooks {
+		eventName, ok := legacyHookEventNames[legacyName]
+		if !ok {
+			continue
+		}
+		specs, err := decodeHookSpecs(raw)
+		if err != nil {
+			return fmt.Errorf("globalHooks [ %s ]: %w", legacyName, err)
+		}
+		s.Settings.Events[eventName] = append(s.Settings.Events[eventName], specs...)
+	}
+	return nil
+}
+
+// decodeHookSpecs converts the untyped globalHooks value for one legacy key
+// (a list of hook mappings as produced by the YAML/TOML decoder) into typed
+// hookSpecs, by round-tripping it through YAML. A value that is not a list
+// of hook mappings is an error.
+func decodeHookSpecs(raw interface{}) ([]hookSpec, error) {
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var specs []hookSpec
+	if err := yaml.Unmarshal(data, &specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// hookContext carries the release/action metadata exposed to hooks, both as
+// HELMSMAN_-prefixed env vars and as the data for a hook's `when` template.
+type hookContext struct {
+	Release   string
+	Namespace string
+	Chart     string
+	Version   string
+	Action    string
+}
+
+// asEnv renders the hook context as HELMSMAN_-prefixed env vars.
+func (c hookContext) asEnv() []string {
+	return []string{
+		"HELMSMAN_RELEASE=" + c.Release,
+		"HELMSMAN_NAMESPACE=" + c.Namespace,
+		"HELMSMAN_CHART=" + c.Chart,
+		"HELMSMAN_VERSION=" + c.Version,
+		"HELMSMAN_ACTION=" + c.Action,
+	}
+}
+
+// mergedHooksFor returns the global hooks for an event followed by a
+// release's own hooks for the same event, so release-scoped hooks extend
+// (rather than replace) global ones. inheritHooks uses it to build each
+// release's events.
+func (s *state) mergedHooksFor(event EventName, releaseHooks []hookSpec) []hookSpec {
+	merged := append([]hookSpec{}, s.Settings.Events[event]...)
+	return append(merged, releaseHooks...)
+}
+
+// runHooks runs an ordered list of hooks for a single lifecycle event,
+// skipping any whose `when` condition evaluates to false, and stopping at
+// the first failing hook unless it is marked continueOnError.
+func runHooks(hooks []hookSpec, ctx hookContext) error {
+	for _, h := range hooks {
+		if h.When != "" && !evalHookCondition(h.When, ctx) {
+			continue
+		}
+		if err := runHook(h, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHook executes a single hook, exposing ctx as HELMSMAN_-prefixed env
+// vars (extended by the hook's own `env` stanza).
+func runHook(h hookSpec, ctx hookContext) error {
+	cmd := exec.Command(h.Command, h.Args...)
+	cmd.Dir = h.WorkingDir
+	cmd.Env = append(os.Environ(), ctx.asEnv()...)
+	for k, v := range h.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	out, err := cmd.CombinedOutput()
+	if !h.Silent && (h.ShowLogs || err != nil) {
+		log.Info(string(out))
+	}
+	if err != nil && !h.ContinueOnError {
+		return fmt.Errorf("hook [ %s ] for event [ %s ] failed: %w", h.Command, ctx.Action, err)
+	}
+	return nil
+}
+
+// evalHookCondition renders a hook's `when` template against its context and
+// reports whether the result is truthy (non-empty and not "false").
+func evalHookCondition(when string, ctx hookContext) bool {
+	tpl, err := template.New("when").Parse(when)
+	if err != nil {
+		log.Error(fmt.Sprintf("hook when-condition %q is not a valid template: %v", when, err))
+		return false
+	}
+
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, ctx); err != nil {
+		log.Error(fmt.Sprintf("hook when-condition %q failed to render: %v", when, err))
+		return false
+	}
+
+	result := strings.TrimSpace(buf.String())
+	return result != "" && result != "false"
+}
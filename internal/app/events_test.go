@@ -0,0 +1,47 @@
+package app
+
+import "testing"
+
+func TestNormalizeEventsAppendsToExistingEvent(t *testing.T) {
+	s := &state{
+		Settings: config{
+			GlobalHooks: map[string]interface{}{
+				"preInstall": []interface{}{
+					map[string]interface{}{"command": "legacy-hook"},
+				},
+			},
+			Events: events{
+				EventPreSync: []hookSpec{{Command: "typed-hook"}},
+			},
+		},
+	}
+
+	if err := s.normalizeEvents(); err != nil {
+		t.Fatalf("normalizeEvents() error = %v", err)
+	}
+
+	hooks := s.Settings.Events[EventPreSync]
+	if len(hooks) != 2 {
+		t.Fatalf("normalizeEvents() = %d hooks for presync, want 2 (typed kept, legacy appended)", len(hooks))
+	}
+	if hooks[0].Command != "typed-hook" || hooks[1].Command != "legacy-hook" {
+		t.Errorf("normalizeEvents() = %#v, want typed-hook then legacy-hook", hooks)
+	}
+}
+
+func TestNormalizeEventsIgnoresUnknownLegacyKey(t *testing.T) {
+	s := &state{
+		Settings: config{
+			GlobalHooks: map[string]interface{}{
+				"notARealHook": []interface{}{map[string]interface{}{"command": "x"}},
+			},
+		},
+	}
+
+	if err := s.normalizeEvents(); err != nil {
+		t.Fatalf("normalizeEvents() error = %v", err)
+	}
+	if len(s.Settings.Events) != 0 {
+		t.Errorf("normalizeEvents() = %#v, want no events for an unrecognized legacy key", s.Settings.Events)
+	}
+}
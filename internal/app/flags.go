@@ -0,0 +1,59 @@
+package app
+
+import (
+	"flag"
+	"strings"
+)
+
+// flags holds the parsed command-line flags consumed throughout this
+// package. The CLI entrypoint calls defineFlags against flag.CommandLine
+// (or a FlagSet of its own) and then flag.Parse() before any state is
+// loaded.
+var flags cliFlags
+
+// cliFlags mirrors the subset of Helmsman's CLI flags referenced by the
+// app package.
+type cliFlags struct {
+	nsOverride      string
+	environment     string
+	skipTemplate    bool
+	stateValuesFile stringSliceFlag
+	stateValuesSet  stringSliceFlag
+	targets         stringSliceFlag
+	groups          stringSliceFlag
+}
+
+// defineFlags registers the flags consumed by this package on fs.
+func defineFlags(fs *flag.FlagSet) {
+	fs.StringVar(&flags.nsOverride, "ns-override", "",
+		"override defined namespaces with this one")
+	fs.StringVar(&flags.environment, "environment", "",
+		"the environment (from the environments stanza) to select")
+	fs.BoolVar(&flags.skipTemplate, "skip-template", false,
+		"do not render the DSF (and any values files it references) through the template engine")
+	fs.Var(&flags.stateValuesFile, "state-values-file",
+		"a values file to merge into the state's values (can be repeated)")
+	fs.Var(&flags.stateValuesSet, "state-values-set",
+		"a comma-separated list of key=value pairs to merge into the state's values (can be repeated)")
+	fs.Var(&flags.targets, "target",
+		"limit the run to this release name (can be repeated); also applied to every helmfiles sub-DSF")
+	fs.Var(&flags.groups, "group",
+		"limit the run to releases in this group (can be repeated); also applied to every helmfiles sub-DSF")
+}
+
+// stringSliceFlag is a flag.Value that appends each occurrence of a
+// repeatable flag (e.g. --state-values-set a=b --state-values-set c=d) to a
+// slice, instead of the last occurrence overwriting the others.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	if s == nil {
+		return ""
+	}
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
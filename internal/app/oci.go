@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ociRegistry holds the credentials needed to `helm registry login` against
+// an OCI-backed chart registry (ECR, GAR, ACR, GHCR, ...). Registries with
+// neither username/password nor a bearer token are treated as anonymous
+// public registries and are skipped during login.
+type ociRegistry struct {
+	Username        string `yaml:"username"`
+	Password        string `yaml:"password"`
+	PasswordFrom    string `yaml:"passwordFrom"`
+	BearerTokenPath string `yaml:"bearerTokenPath"`
+}
+
+// isOCIChart reports whether a release's chart reference points directly at
+// an OCI registry (e.g. "oci://registry/path/name") rather than a named
+// classic Helm repo.
+func isOCIChart(chart string) bool {
+	return strings.HasPrefix(chart, "oci://")
+}
+
+// isOCIRepo reports whether a helmRepos entry is an OCI registry rather than
+// a classic index.yaml repo.
+func isOCIRepo(url string) bool {
+	return strings.HasPrefix(url, "oci://")
+}
+
+// loginOCIRegistries runs `helm registry login` for every configured
+// ociRegistries entry that carries credentials, reusing BearerTokenPath
+// semantics for registries that authenticate via a bearer token file.
+func (s *state) loginOCIRegistries() error {
+	for name, reg := range s.Settings.OCIRegistries {
+		username := reg.Username
+		password := reg.Password
+
+		if reg.PasswordFrom != "" {
+			data, err := os.ReadFile(reg.PasswordFrom)
+			if err != nil {
+				return fmt.Errorf("ociRegistries [ %s ]: passwordFrom [ %s ] is not found", name, reg.PasswordFrom)
+			}
+			password = strings.TrimSpace(string(data))
+		}
+
+		if reg.BearerTokenPath != "" {
+			token, err := os.ReadFile(reg.BearerTokenPath)
+			if err != nil {
+				return fmt.Errorf("ociRegistries [ %s ]: bearerTokenPath [ %s ] is not found", name, reg.BearerTokenPath)
+			}
+			username = "bearer"
+			password = strings.TrimSpace(string(token))
+		}
+
+		if username == "" && password == "" {
+			// anonymous public registry -- nothing to authenticate
+			continue
+		}
+
+		cmd := exec.Command("helm", "registry", "login", name, "--username", username, "--password", password)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("helm registry login [ %s ] failed: %s", name, string(out))
+		}
+	}
+	return nil
+}
+
+// validateOCIChart checks chart existence/version for an oci:// chart
+// reference using `helm show chart`, since `helm search repo` does not work
+// against OCI registries.
+func validateOCIChart(apps, chart, version string, c chan string) {
+	args := []string{"show", "chart", chart}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+
+	cmd := exec.Command("helm", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		c <- fmt.Sprintf("chart [ %s ] version [ %s ] for apps [ %s ] was not found in the OCI registry -- %s",
+			chart, version, apps, strings.TrimSpace(string(out)))
+		return
+	}
+	c <- ""
+}
@@ -0,0 +1,70 @@
+package app
+
+import "testing"
+
+func TestIsOCIChart(t *testing.T) {
+	cases := map[string]bool{
+		"oci://registry.example.com/charts/app": true,
+		"stable/app":                            false,
+		"https://charts.example.com":            false,
+	}
+	for chart, want := range cases {
+		if got := isOCIChart(chart); got != want {
+			t.Errorf("isOCIChart(%q) = %v, want %v", chart, got, want)
+		}
+	}
+}
+
+func TestIsOCIRepo(t *testing.T) {
+	cases := map[string]bool{
+		"oci://registry.example.com/charts": true,
+		"https://charts.example.com":        false,
+	}
+	for url, want := range cases {
+		if got := isOCIRepo(url); got != want {
+			t.Errorf("isOCIRepo(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestLoginOCIRegistriesSkipsAnonymousRegistries(t *testing.T) {
+	s := &state{
+		Settings: config{
+			OCIRegistries: map[string]ociRegistry{
+				"anonymous.example.com": {},
+			},
+		},
+	}
+
+	if err := s.loginOCIRegistries(); err != nil {
+		t.Errorf("loginOCIRegistries() error = %v, want nil for an anonymous (credential-less) registry", err)
+	}
+}
+
+func TestLoginOCIRegistriesErrorsOnMissingPasswordFromFile(t *testing.T) {
+	s := &state{
+		Settings: config{
+			OCIRegistries: map[string]ociRegistry{
+				"creds.example.com": {Username: "u", PasswordFrom: "/does/not/exist"},
+			},
+		},
+	}
+
+	if err := s.loginOCIRegistries(); err == nil {
+		t.Error("loginOCIRegistries() should error when passwordFrom points at a missing file")
+	}
+}
+
+func TestLoginOCIRegistriesErrorsOnMissingBearerTokenFile(t *testing.T) {
+	s := &state{
+		Settings: config{
+			OCIRegistries: map[string]ociRegistry{
+				"bearer.example.com": {BearerTokenPath: "/does/not/exist"},
+			},
+		},
+	}
+
+	if err := s.loginOCIRegistries(); err == nil {
+		t.Error("loginOCIRegistries() should error when bearerTokenPath points at a missing file")
+	}
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// release represents a single `apps` entry in the DSF: one Helm release to
+// manage in a namespace, plus the per-release overrides (values, hooks,
+// history limit) layered on top of the DSF-wide settings.
+type release struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Namespace   string                 `yaml:"namespace"`
+	Group       string                 `yaml:"group"`
+	Chart       string                 `yaml:"chart"`
+	Version     string                 `yaml:"version"`
+	Values      map[string]interface{} `yaml:"values"`
+	SetString   map[string]interface{} `yaml:"setString"`
+	Hooks       map[string]interface{} `yaml:"hooks"`
+	Events      events                 `yaml:"events"`
+	MaxHistory  int                    `yaml:"maxHistory"`
+
+	disabled bool
+}
+
+// Disable marks the release as excluded from the current run, e.g. because
+// it fell outside a --target/--group selection.
+func (r *release) Disable() {
+	r.disabled = true
+}
+
+// isConsideredToRun reports whether the release should be acted on in the
+// current run.
+func (r *release) isConsideredToRun() bool {
+	return !r.disabled
+}
+
+// overrideNamespace replaces the release's namespace, used by
+// --ns-override.
+func (r *release) overrideNamespace(newNs string) {
+	r.Namespace = newNs
+}
+
+// inheritMaxHistory defaults the release's maxHistory to
+// settings.globalMaxHistory when it wasn't set explicitly.
+func (r *release) inheritMaxHistory(s *state) {
+	if r.MaxHistory == 0 {
+		r.MaxHistory = s.Settings.GlobalMaxHistory
+	}
+}
+
+// inheritHooks normalizes the release's own legacy `hooks` map into the
+// typed Events model, then extends -- rather than replaces -- its hooks
+// with the DSF-wide settings.Events/globalHooks for each event, so a
+// release's own hooks run in addition to the global ones instead of
+// instead of them.
+func (r *release) inheritHooks(s *state) error {
+	if r.Events == nil {
+		r.Events = events{}
+	}
+	for legacyName, raw := range r.Hooks {
+		eventName, ok := legacyHookEventNames[legacyName]
+		if !ok {
+			continue
+		}
+		specs, err := decodeHookSpecs(raw)
+		if err != nil {
+			return fmt.Errorf("release [ %s ] hooks [ %s ]: %w", r.Name, legacyName, err)
+		}
+		r.Events[eventName] = append(r.Events[eventName], specs...)
+	}
+
+	merged := events{}
+	for event := range s.Settings.Events {
+		merged[event] = s.mergedHooksFor(event, r.Events[event])
+	}
+	for event := range r.Events {
+		if _, ok := merged[event]; !ok {
+			merged[event] = s.mergedHooksFor(event, r.Events[event])
+		}
+	}
+	r.Events = merged
+	return nil
+}
+
+// validate checks that the release has the minimum fields required to be
+// applied, and that its name is unique within its namespace.
+func (r *release) validate(appLabel string, names map[string]map[string]bool, s *state) error {
+	if r.Namespace == "" {
+		return fmt.Errorf("release [ %s ] is missing a namespace", appLabel)
+	}
+	if flags.nsOverride == "" && !s.isNamespaceDefined(r.Namespace) {
+		return fmt.Errorf("release [ %s ] namespace [ %s ] is not defined in the namespaces stanza", appLabel, r.Namespace)
+	}
+	if r.Chart == "" {
+		return fmt.Errorf("release [ %s ] is missing a chart", appLabel)
+	}
+	if r.Version == "" {
+		return fmt.Errorf("release [ %s ] is missing a version", appLabel)
+	}
+
+	if names[r.Namespace] == nil {
+		names[r.Namespace] = map[string]bool{}
+	}
+	if names[r.Namespace][r.Name] {
+		return errors.New("release name [ " + r.Name + " ] is used more than once in namespace [ " + r.Namespace + " ]")
+	}
+	names[r.Namespace][r.Name] = true
+
+	return nil
+}
+
+// label reapplies the MANAGED-BY=HELMSMAN label to the release's storage
+// backend objects (e.g. its Helm release secrets). It sets no context
+// label: the DSF's context is not passed to it.
+func (r *release) label(storageBackend string) {
+	selector := "owner=helm,name=" + r.Name
+	args := []string{"label", storageBackend, "-n", r.Namespace, "-l", selector, "--overwrite", "MANAGED-BY=HELMSMAN"}
+	cmd := exec.Command("kubectl", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.Error(fmt.Sprintf("failed to label release [ %s ]: %s", r.Name, strings.TrimSpace(string(out))))
+	}
+}
+
+// print prints the release's fields.
+func (r *release) print() {
+	fmt.Println(r.Name + ": ")
+	fmt.Printf("%+v\n", *r)
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Switch-TV/helmsman/internal/app/secrets"
+)
+
+// resolveSecrets walks the parsed state via reflection and expands any
+// "ref+<scheme>://" secret references found in settings, release values,
+// and set-strings, substituting the resolved plaintext in place. It
+// replaces the eyaml-only secret path with the generic secrets.Resolve
+// registry, while keeping EyamlEnabled/EyamlPrivateKeyPath/EyamlPublicKeyPath
+// as the configuration surface for the "ref+eyaml://" backend.
+func (s *state) resolveSecrets() error {
+	secrets.ConfigureEyaml(s.Settings.EyamlEnabled, s.Settings.EyamlPrivateKeyPath, s.Settings.EyamlPublicKeyPath)
+
+	return resolveSecretsIn(context.Background(), reflect.ValueOf(s))
+}
+
+// resolveSecretsIn recursively walks v, replacing any string that looks
+// like a secret ref with its resolved plaintext.
+func resolveSecretsIn(ctx context.Context, v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveSecretsIn(ctx, v.Elem())
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			if err := resolveSecretsIn(ctx, f); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			resolved, err := resolveSecretsMapValue(ctx, v.MapIndex(key))
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(key, resolved)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveSecretsIn(ctx, v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.String:
+		if v.CanSet() {
+			resolved, err := secrets.Resolve(ctx, v.String())
+			if err != nil {
+				return err
+			}
+			v.SetString(resolved)
+		}
+	}
+
+	return nil
+}
+
+// resolveSecretsMapValue resolves secret refs within a single map value and
+// returns the value to write back with SetMapIndex. Map values are never
+// addressable via reflection, so it can't simply recurse into them in
+// place; and for an interface-typed map (e.g. map[string]interface{}, the
+// type of s.Values and release.Hooks) val.Kind() always reports Interface
+// rather than the kind of what's actually stored, so the concrete
+// underlying value is unwrapped first -- otherwise the plain-string fast
+// path here never triggers and a secret ref sitting in the generic values
+// bag is silently left unresolved.
+func resolveSecretsMapValue(ctx context.Context, val reflect.Value) (reflect.Value, error) {
+	if !val.IsValid() {
+		return val, nil
+	}
+
+	concrete := val
+	if concrete.Kind() == reflect.Interface {
+		if concrete.IsNil() {
+			return val, nil
+		}
+		concrete = concrete.Elem()
+	}
+
+	if concrete.Kind() == reflect.String {
+		resolved, err := secrets.Resolve(ctx, concrete.String())
+		if err != nil {
+			return val, err
+		}
+		return reflect.ValueOf(resolved), nil
+	}
+
+	// copy into an addressable value so nested maps/structs/slices can be
+	// mutated, then write the (possibly now-resolved) copy back.
+	elem := reflect.New(concrete.Type()).Elem()
+	elem.Set(concrete)
+	if err := resolveSecretsIn(ctx, elem); err != nil {
+		return val, err
+	}
+	return elem, nil
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Switch-TV/helmsman/internal/app/secrets"
+)
+
+type stubResolver struct{}
+
+func (stubResolver) Resolve(_ context.Context, _ string) (string, error) {
+	return "plaintext", nil
+}
+
+func init() {
+	secrets.Register("stub", stubResolver{})
+}
+
+func TestResolveSecretsInResolvesInterfaceTypedMapValues(t *testing.T) {
+	values := map[string]interface{}{
+		"password": "ref+stub://password",
+		"nested": map[string]interface{}{
+			"token": "ref+stub://token",
+		},
+		"untouched": "plain",
+	}
+
+	if err := resolveSecretsIn(context.Background(), reflect.ValueOf(values)); err != nil {
+		t.Fatalf("resolveSecretsIn() error = %v", err)
+	}
+
+	if values["password"] != "plaintext" {
+		t.Errorf("values[\"password\"] = %#v, want it resolved to \"plaintext\"", values["password"])
+	}
+	nested, ok := values["nested"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("values[\"nested\"] = %#v, want a map[string]interface{}", values["nested"])
+	}
+	if nested["token"] != "plaintext" {
+		t.Errorf("values[\"nested\"][\"token\"] = %#v, want it resolved to \"plaintext\"", nested["token"])
+	}
+	if values["untouched"] != "plain" {
+		t.Errorf("values[\"untouched\"] = %#v, want it left unchanged", values["untouched"])
+	}
+}
@@ -0,0 +1,29 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	Register("awssm", awsSecretsManagerResolver{})
+}
+
+// awsSecretsManagerResolver resolves "ref+awssm://secret-id" refs via the
+// aws CLI's secretsmanager get-secret-value, which already carries the
+// user's AWS credentials/profile/region configuration.
+type awsSecretsManagerResolver struct{}
+
+func (awsSecretsManagerResolver) Resolve(ctx context.Context, uri string) (string, error) {
+	secretID := strings.TrimPrefix(uri, "ref+awssm://")
+
+	cmd := exec.CommandContext(ctx, "aws", "secretsmanager", "get-secret-value",
+		"--secret-id", secretID, "--query", "SecretString", "--output", "text")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("aws secretsmanager get-secret-value [ %s ] failed: %w", secretID, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var eyamlMu sync.RWMutex
+var eyamlKeys struct {
+	enabled        bool
+	privateKeyPath string
+	publicKeyPath  string
+}
+
+// ConfigureEyaml wires up the eyaml backend from the DSF's settings
+// (eyamlEnabled/eyamlPrivateKeyPath/eyamlPublicKeyPath), preserving the
+// existing eyaml configuration surface as the "ref+eyaml://" backend.
+func ConfigureEyaml(enabled bool, privateKeyPath, publicKeyPath string) {
+	eyamlMu.Lock()
+	defer eyamlMu.Unlock()
+	eyamlKeys.enabled = enabled
+	eyamlKeys.privateKeyPath = privateKeyPath
+	eyamlKeys.publicKeyPath = publicKeyPath
+}
+
+func init() {
+	Register("eyaml", eyamlResolver{})
+}
+
+// eyamlResolver resolves "ref+eyaml://path/to/secret.eyaml#key" refs by
+// shelling out to the eyaml CLI with the configured private/public keys.
+type eyamlResolver struct{}
+
+func (eyamlResolver) Resolve(ctx context.Context, uri string) (string, error) {
+	eyamlMu.RLock()
+	enabled, privateKeyPath, publicKeyPath := eyamlKeys.enabled, eyamlKeys.privateKeyPath, eyamlKeys.publicKeyPath
+	eyamlMu.RUnlock()
+
+	if !enabled {
+		return "", fmt.Errorf("eyaml ref [ %s ] found but eyamlEnabled is false in settings", uri)
+	}
+
+	ref := strings.TrimPrefix(uri, "ref+eyaml://")
+	path, key, ok := cutLast(ref, "#")
+	if !ok {
+		path, key = ref, ""
+	}
+
+	args := []string{"decrypt", "-f", path, "--pkcs7-private-key", privateKeyPath, "--pkcs7-public-key", publicKeyPath}
+	if key != "" {
+		args = append(args, "--matching-key", key)
+	}
+
+	cmd := exec.CommandContext(ctx, "eyaml", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("eyaml decrypt [ %s ] failed: %w", path, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,24 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+func init() {
+	Register("file", fileResolver{})
+}
+
+// fileResolver resolves "ref+file://path" refs by reading the file's
+// contents from disk, trimming any trailing newlines.
+type fileResolver struct{}
+
+func (fileResolver) Resolve(ctx context.Context, uri string) (string, error) {
+	path := strings.TrimPrefix(uri, "ref+file://")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
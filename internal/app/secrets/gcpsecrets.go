@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	Register("gcpsecrets", gcpSecretsResolver{})
+}
+
+// gcpSecretsResolver resolves "ref+gcpsecrets://project/name#version" refs
+// (version defaults to "latest") via the gcloud CLI, which already carries
+// the user's gcloud auth/project configuration.
+type gcpSecretsResolver struct{}
+
+func (gcpSecretsResolver) Resolve(ctx context.Context, uri string) (string, error) {
+	ref := strings.TrimPrefix(uri, "ref+gcpsecrets://")
+	name, version, ok := cutLast(ref, "#")
+	if !ok {
+		name, version = ref, "latest"
+	}
+	project, secretName, ok := cutFirst(name, "/")
+	if !ok {
+		return "", fmt.Errorf("gcpsecrets ref [ %s ] must be of the form project/name[#version]", uri)
+	}
+
+	cmd := exec.CommandContext(ctx, "gcloud", "secrets", "versions", "access", version,
+		"--project", project, "--secret", secretName)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gcloud secrets versions access [ %s/%s ] failed: %w", project, secretName, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// cutFirst splits s on the first occurrence of sep.
+func cutFirst(s, sep string) (before, after string, ok bool) {
+	i := strings.Index(s, sep)
+	if i == -1 {
+		return "", "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
@@ -0,0 +1,88 @@
+// Package secrets implements a vals-style reference resolver: "ref+<scheme>://..."
+// URIs embedded anywhere in the desired state are expanded to their
+// plaintext value by the backend registered for that scheme.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Resolver resolves a single ref+<scheme>://... URI into its plaintext
+// value.
+type Resolver interface {
+	Resolve(ctx context.Context, uri string) (string, error)
+}
+
+var (
+	mu        sync.RWMutex
+	resolvers = map[string]Resolver{}
+
+	cacheMu sync.Mutex
+	cache   = map[string]string{}
+)
+
+// Register associates a Resolver with a ref+<scheme>:// prefix, e.g. "vault"
+// for "ref+vault://...". Backends call this from their own init().
+func Register(scheme string, r Resolver) {
+	mu.Lock()
+	defer mu.Unlock()
+	resolvers[scheme] = r
+}
+
+// IsRef reports whether value looks like a "ref+<scheme>://..." secret
+// reference.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, "ref+")
+}
+
+// Resolve expands a "ref+<scheme>://..." URI using the resolver registered
+// for its scheme, caching the result per-URI for the lifetime of the run.
+// Values that are not refs are returned unchanged.
+func Resolve(ctx context.Context, uri string) (string, error) {
+	if !IsRef(uri) {
+		return uri, nil
+	}
+
+	cacheMu.Lock()
+	if v, ok := cache[uri]; ok {
+		cacheMu.Unlock()
+		return v, nil
+	}
+	cacheMu.Unlock()
+
+	scheme, err := schemeOf(uri)
+	if err != nil {
+		return "", err
+	}
+
+	mu.RLock()
+	r, ok := resolvers[scheme]
+	mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no secret resolver registered for scheme [ ref+%s:// ]", scheme)
+	}
+
+	value, err := r.Resolve(ctx, uri)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret [ %s ]: %w", uri, err)
+	}
+
+	cacheMu.Lock()
+	cache[uri] = value
+	cacheMu.Unlock()
+
+	return value, nil
+}
+
+// schemeOf extracts the backend scheme out of a "ref+<scheme>://..." URI.
+func schemeOf(uri string) (string, error) {
+	rest := strings.TrimPrefix(uri, "ref+")
+	i := strings.Index(rest, "://")
+	if i == -1 {
+		return "", fmt.Errorf("malformed secret ref [ %s ], expected ref+<scheme>://...", uri)
+	}
+	return rest[:i], nil
+}
@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIsRef(t *testing.T) {
+	cases := map[string]bool{
+		"ref+vault://path#field": true,
+		"ref+file://a/b":         true,
+		"plain-string":           false,
+		"":                       false,
+	}
+	for value, want := range cases {
+		if got := IsRef(value); got != want {
+			t.Errorf("IsRef(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestSchemeOf(t *testing.T) {
+	scheme, err := schemeOf("ref+vault://path/to/secret#field")
+	if err != nil {
+		t.Fatalf("schemeOf() error = %v", err)
+	}
+	if scheme != "vault" {
+		t.Errorf("schemeOf() = %q, want %q", scheme, "vault")
+	}
+
+	if _, err := schemeOf("ref+malformed"); err == nil {
+		t.Error("schemeOf() on a ref missing \"://\" should error")
+	}
+}
+
+func TestResolvePassesThroughNonRefValues(t *testing.T) {
+	got, err := Resolve(context.Background(), "plain-string")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got != "plain-string" {
+		t.Errorf("Resolve() = %q, want the value unchanged", got)
+	}
+}
+
+func TestResolveErrorsOnUnregisteredScheme(t *testing.T) {
+	if _, err := Resolve(context.Background(), "ref+no-such-scheme://x"); err == nil {
+		t.Error("Resolve() with an unregistered scheme should error")
+	}
+}
+
+type countingResolver struct {
+	calls int
+}
+
+func (c *countingResolver) Resolve(_ context.Context, _ string) (string, error) {
+	c.calls++
+	return "resolved", nil
+}
+
+func TestResolveCachesPerURI(t *testing.T) {
+	r := &countingResolver{}
+	Register("counting-test", r)
+
+	uri := "ref+counting-test://some/path"
+	for i := 0; i < 3; i++ {
+		got, err := Resolve(context.Background(), uri)
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if got != "resolved" {
+			t.Errorf("Resolve() = %q, want %q", got, "resolved")
+		}
+	}
+	if r.calls != 1 {
+		t.Errorf("underlying resolver was called %d times, want 1 (cached after the first)", r.calls)
+	}
+}
+
+type erroringResolver struct{}
+
+func (erroringResolver) Resolve(_ context.Context, _ string) (string, error) {
+	return "", errors.New("boom")
+}
+
+func TestResolveWrapsBackendError(t *testing.T) {
+	Register("erroring-test", erroringResolver{})
+
+	if _, err := Resolve(context.Background(), "ref+erroring-test://x"); err == nil {
+		t.Error("Resolve() should propagate the backend's error")
+	}
+}
+
+func TestCutLast(t *testing.T) {
+	before, after, ok := cutLast("path/to/secret#field", "#")
+	if !ok || before != "path/to/secret" || after != "field" {
+		t.Errorf("cutLast() = (%q, %q, %v), want (%q, %q, true)", before, after, ok, "path/to/secret", "field")
+	}
+
+	if _, _, ok := cutLast("no-separator", "#"); ok {
+		t.Error("cutLast() on a string without the separator should report ok=false")
+	}
+}
+
+func TestCutFirst(t *testing.T) {
+	before, after, ok := cutFirst("project/secret-name", "/")
+	if !ok || before != "project" || after != "secret-name" {
+		t.Errorf("cutFirst() = (%q, %q, %v), want (%q, %q, true)", before, after, ok, "project", "secret-name")
+	}
+
+	if _, _, ok := cutFirst("no-separator", "/"); ok {
+		t.Error("cutFirst() on a string without the separator should report ok=false")
+	}
+}
@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	Register("sops", sopsResolver{})
+}
+
+// sopsResolver resolves "ref+sops://path/to/file.yaml#data_key" refs by
+// shelling out to the sops CLI to decrypt the file and extract the key.
+type sopsResolver struct{}
+
+func (sopsResolver) Resolve(ctx context.Context, uri string) (string, error) {
+	ref := strings.TrimPrefix(uri, "ref+sops://")
+	path, key, ok := cutLast(ref, "#")
+	if !ok {
+		path, key = ref, ""
+	}
+
+	args := []string{"--decrypt"}
+	if key != "" {
+		args = append(args, "--extract", "[\""+key+"\"]")
+	}
+	args = append(args, path)
+
+	cmd := exec.CommandContext(ctx, "sops", args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("sops --decrypt [ %s ] failed: %w", path, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
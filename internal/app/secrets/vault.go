@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	Register("vault", vaultResolver{})
+}
+
+// vaultResolver resolves "ref+vault://path/to/secret#field" refs by
+// shelling out to the vault CLI, which already carries the user's
+// VAULT_ADDR/VAULT_TOKEN configuration.
+type vaultResolver struct{}
+
+func (vaultResolver) Resolve(ctx context.Context, uri string) (string, error) {
+	ref := strings.TrimPrefix(uri, "ref+vault://")
+	path, field, ok := cutLast(ref, "#")
+	if !ok {
+		return "", fmt.Errorf("vault ref [ %s ] must be of the form path/to/secret#field", uri)
+	}
+
+	cmd := exec.CommandContext(ctx, "vault", "kv", "get", "-field="+field, path)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("vault kv get [ %s ] failed: %w", path, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// cutLast splits s on the last occurrence of sep, the way a vals ref
+// separates its path from the field to extract.
+func cutLast(s, sep string) (before, after string, ok bool) {
+	i := strings.LastIndex(s, sep)
+	if i == -1 {
+		return "", "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -26,32 +27,168 @@ type config struct {
 	EyamlPrivateKeyPath string                 `yaml:"eyamlPrivateKeyPath"`
 	EyamlPublicKeyPath  string                 `yaml:"eyamlPublicKeyPath"`
 	GlobalHooks         map[string]interface{} `yaml:"globalHooks"`
+	Events              events                 `yaml:"events"`
 	GlobalMaxHistory    int                    `yaml:"globalMaxHistory"`
+	OCIRegistries       map[string]ociRegistry `yaml:"ociRegistries"`
 }
 
 // state type represents the desired state of applications on a k8s cluster.
 type state struct {
-	Metadata               map[string]string     `yaml:"metadata"`
-	Certificates           map[string]string     `yaml:"certificates"`
-	Settings               config                `yaml:"settings"`
-	Context                string                `yaml:"context"`
-	Namespaces             map[string]*namespace `yaml:"namespaces"`
-	HelmRepos              map[string]string     `yaml:"helmRepos"`
-	PreconfiguredHelmRepos []string              `yaml:"preconfiguredHelmRepos"`
-	Apps                   map[string]*release   `yaml:"apps"`
-	AppsTemplates          map[string]*release   `yaml:"appsTemplates,omitempty"`
+	Metadata               map[string]string       `yaml:"metadata"`
+	Certificates           map[string]string       `yaml:"certificates"`
+	Settings               config                  `yaml:"settings"`
+	Context                string                  `yaml:"context"`
+	Namespaces             map[string]*namespace   `yaml:"namespaces"`
+	HelmRepos              map[string]string       `yaml:"helmRepos"`
+	PreconfiguredHelmRepos []string                `yaml:"preconfiguredHelmRepos"`
+	Apps                   map[string]*release     `yaml:"apps"`
+	AppsTemplates          map[string]*release     `yaml:"appsTemplates,omitempty"`
+	Environments           map[string]*environment `yaml:"environments"`
+	Values                 map[string]interface{}  `yaml:"values"`
+	Bases                  []string                `yaml:"bases"`
+	Helmfiles              []SubStateSpec          `yaml:"helmfiles"`
+	SubStates              []*state
 	TargetMap              map[string]bool
 }
 
-// invokes either yaml or toml parser considering file extension
+// fromFile is the entry point for parsing a DSF. It loads the file (merging
+// in any bases, see loadFile), then resolves the active environment's
+// values and any CLI value overrides exactly once, against the
+// fully-merged result -- so a base does not need to redeclare the root's
+// selected --environment to be mergeable with it. Finally, any `helmfiles`
+// sub-DSFs are loaded into a combined plan.
 func (s *state) fromFile(file string) (bool, string) {
+	var env *environment
+	var values map[string]interface{}
+	if !flags.skipTemplate {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return false, err.Error()
+		}
+		env, err = peekEnvironment(file, data)
+		if err != nil {
+			return false, err.Error()
+		}
+
+		// Peek the file's own values stanza, and that of the selected
+		// environment, so the root file's own template pass has a real
+		// .Values to work with -- rather than always rendering against nil,
+		// which breaks any DSF referencing its own declared values.
+		values, err = peekValues(file, data)
+		if err != nil {
+			return false, err.Error()
+		}
+		if values == nil {
+			values = map[string]interface{}{}
+		}
+		if env != nil {
+			envValues := map[string]interface{}{}
+			mergeValues(envValues, env.Defaults)
+			for _, f := range env.Values {
+				fileValues, err := readValuesFile(f)
+				if err != nil {
+					return false, err.Error()
+				}
+				mergeValues(envValues, fileValues)
+			}
+			mergeValues(envValues, values)
+			values = envValues
+		}
+
+		overrides, err := computeStateValueOverrides()
+		if err != nil {
+			return false, err.Error()
+		}
+		mergeValues(values, overrides)
+	}
+
+	if ok, msg := s.loadFile(file, env, values, map[string]bool{}); !ok {
+		return false, msg
+	}
+
+	if err := s.resolveEnvironment(); err != nil {
+		return false, err.Error()
+	}
+
+	if err := s.applyStateValueOverrides(); err != nil {
+		return false, err.Error()
+	}
+
+	subStates, err := s.loadHelmfiles()
+	if err != nil {
+		return false, err.Error()
+	}
+	s.SubStates = subStates
+
+	return true, ""
+}
+
+// loadFile renders file through the template engine (unless
+// --skip-template is set) and parses it, then recursively merges in its
+// bases. seen is threaded through every recursive load -- including
+// through each base's own bases -- so a cycle anywhere in the chain is
+// caught, rather than resetting detection at each level.
+//
+// env and values are the active environment and merged .Values template
+// context for *this file's own* render; bases are loaded with neither of
+// their own (see resolveBases), since --environment/--state-values-*
+// resolution against the fully-merged values only happens once, by
+// fromFile, after all bases have been merged in -- a base does not need to
+// redeclare the root's selected environment or values.
+func (s *state) loadFile(file string, env *environment, values map[string]interface{}, seen map[string]bool) (bool, string) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false, err.Error()
+	}
+	if seen[abs] {
+		return false, fmt.Sprintf("cycle detected while resolving bases -- [ %s ] is already being loaded", file)
+	}
+	seen[abs] = true
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	if !flags.skipTemplate {
+		rendered, err := renderTemplate(file, data, env, values)
+		if err != nil {
+			return false, err.Error()
+		}
+		data = rendered
+	}
+
+	tmp, err := os.CreateTemp("", "helmsman-*"+filepath.Ext(file))
+	if err != nil {
+		return false, err.Error()
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, err.Error()
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err.Error()
+	}
+
+	var ok bool
+	var msg string
 	if isOfType(file, []string{".toml"}) {
-		return fromTOML(file, s)
+		ok, msg = fromTOML(tmp.Name(), s)
 	} else if isOfType(file, []string{".yaml", ".yml"}) {
-		return fromYAML(file, s)
+		ok, msg = fromYAML(tmp.Name(), s)
 	} else {
 		return false, "State file does not have toml/yaml extension."
 	}
+	if !ok {
+		return ok, msg
+	}
+
+	if err := s.resolveBases(file, seen); err != nil {
+		return false, err.Error()
+	}
+
+	return true, ""
 }
 
 func (s *state) toFile(file string) {
@@ -64,7 +201,7 @@ func (s *state) toFile(file string) {
 	}
 }
 
-func (s *state) setDefaults() {
+func (s *state) setDefaults() error {
 	if s.Settings.StorageBackend != "" {
 		os.Setenv("HELM_DRIVER", s.Settings.StorageBackend)
 	} else {
@@ -77,15 +214,40 @@ func (s *state) setDefaults() {
 		s.Context = defaultContextName
 	}
 
+	// normalize the legacy globalHooks shape into the typed Events model
+	// before inheritHooks merges it into each release below -- otherwise a
+	// DSF using only globalHooks (no events stanza) would have nothing to
+	// inherit, since normalizeEvents was previously only reached from
+	// validate(), which runs after setDefaults.
+	if err := s.normalizeEvents(); err != nil {
+		return fmt.Errorf("events validation failed -- %w", err)
+	}
+
 	for name, r := range s.Apps {
 		// Default app.Name to state name when unset
 		if r.Name == "" {
 			r.Name = name
 		}
-		// inherit globalHooks if local ones are not set
-		r.inheritHooks(s)
+		// extend the release's own hooks with the DSF-wide ones
+		if err := r.inheritHooks(s); err != nil {
+			return fmt.Errorf("release [ %s ]: %w", name, err)
+		}
 		r.inheritMaxHistory(s)
 	}
+
+	// expand any "ref+<scheme>://" secret references (settings, release
+	// values, set-strings) now that defaults are in place
+	if err := s.resolveSecrets(); err != nil {
+		return err
+	}
+
+	// log in to any OCI registries that carry credentials, now that
+	// settings (and any secret refs within them) are resolved
+	if err := s.loginOCIRegistries(); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 // validate validates that the values specified in the desired state are valid according to the desired state spec.
@@ -134,6 +296,16 @@ func (s *state) validate() error {
 		}
 	}
 
+	// events validation -- normalizeEvents has already run, in setDefaults,
+	// before any release's hooks were inherited from it
+	for name, hooks := range s.Settings.Events {
+		for _, h := range hooks {
+			if h.Command == "" {
+				return fmt.Errorf("events validation failed -- a hook for event [ %s ] is missing a command", name)
+			}
+		}
+	}
+
 	// slack webhook validation (if provided)
 	if s.Settings.SlackWebhook != "" {
 		if _, err := url.ParseRequestURI(s.Settings.SlackWebhook); err != nil {
@@ -189,6 +361,11 @@ func (s *state) validate() error {
 
 	// repos
 	for k, v := range s.HelmRepos {
+		if isOCIRepo(v) {
+			// OCI registries are authenticated via `helm registry login`, not
+			// `helm repo add`, and don't carry an index.yaml to validate.
+			continue
+		}
 		_, err := url.ParseRequestURI(v)
 		if err != nil {
 			return errors.New("repos validation failed -- repo [" + k + " ] " +
@@ -203,6 +380,14 @@ func (s *state) validate() error {
 		}
 	}
 
+	// validate every `helmfiles` sub-DSF too, so the combined plan is
+	// checked as a whole rather than only the root DSF.
+	for _, sub := range s.SubStates {
+		if err := sub.validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -243,7 +428,11 @@ func (s *state) validateReleaseCharts() error {
 					wg.Done()
 					<-sem
 				}()
-				validateChart(concattedApps, chart, version, c)
+				if isOCIChart(chart) {
+					validateOCIChart(concattedApps, chart, version, c)
+				} else {
+					validateChart(concattedApps, chart, version, c)
+				}
 			}(concattedApps, ch, v)
 		}
 	}
@@ -324,16 +513,32 @@ func (s *state) disableUntargettedApps() {
 	}
 }
 
-// updateContextLabels applies Helmsman labels including overriding any previously-set context with the one found in the DSF
+// updateContextLabels reapplies the Helmsman MANAGED-BY label to each targeted release (no context label is written),
+// running each release's preapply/postapply hooks (its own, extended with any DSF-wide ones) around the label update.
 func (s *state) updateContextLabels() {
 	for _, r := range s.Apps {
 		if r.isConsideredToRun() {
+			ctx := hookContext{Release: r.Name, Namespace: r.Namespace, Chart: r.Chart, Version: r.Version, Action: "apply"}
+
+			if err := runHooks(r.Events[EventPreApply], ctx); err != nil {
+				log.Error(err.Error())
+				continue
+			}
+
 			log.Info("Updating context and reapplying Helmsman labels for release [ " + r.Name + " ]")
 			r.label(s.Settings.StorageBackend)
+
+			if err := runHooks(r.Events[EventPostApply], ctx); err != nil {
+				log.Error(err.Error())
+			}
 		} else {
 			log.Warning(r.Name + " is not in the target group and therefore context and labels are not changed.")
 		}
 	}
+
+	for _, sub := range s.SubStates {
+		sub.updateContextLabels()
+	}
 }
 
 // print prints the desired state
@@ -367,4 +572,10 @@ func (s *state) print() {
 	for t := range s.TargetMap {
 		fmt.Println(t)
 	}
+
+	for _, sub := range s.SubStates {
+		fmt.Println("\nHelmfile: ")
+		fmt.Println("--------------- ")
+		sub.print()
+	}
 }
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/sprig/v3"
+	"gopkg.in/yaml.v2"
+)
+
+// peekEnvironment unmarshals only the `environments` stanza out of the raw
+// DSF bytes, ahead of templating and full parsing, and returns the one
+// selected by --environment (nil if none was requested). This is what lets
+// the rest of the document be rendered with the environment's context
+// available before it is fully parsed.
+func peekEnvironment(file string, data []byte) (*environment, error) {
+	if flags.environment == "" {
+		return nil, nil
+	}
+
+	var peek struct {
+		Environments map[string]*environment `yaml:"environments" toml:"environments"`
+	}
+	if isOfType(file, []string{".toml"}) {
+		if _, err := toml.Decode(string(data), &peek); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	} else if err := yaml.Unmarshal(data, &peek); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	env, ok := peek.Environments[flags.environment]
+	if !ok {
+		return nil, fmt.Errorf("environment [ %s ] is not defined in the environments stanza", flags.environment)
+	}
+	return env, nil
+}
+
+// peekValues unmarshals only the `values` stanza out of the raw DSF bytes,
+// ahead of templating and full parsing, the same way peekEnvironment peeks
+// `environments` -- so the file's own values stanza can be exposed as the
+// .Values template context for its own render pass (these are the raw,
+// unrendered values; template expressions inside them are not expanded).
+func peekValues(file string, data []byte) (map[string]interface{}, error) {
+	var peek struct {
+		Values map[string]interface{} `yaml:"values" toml:"values"`
+	}
+	if isOfType(file, []string{".toml"}) {
+		if _, err := toml.Decode(string(data), &peek); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	} else if err := yaml.Unmarshal(data, &peek); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return peek.Values, nil
+}
+
+// renderTemplate renders raw DSF (or valuesFile) bytes through text/template
+// with a sprig function set plus Helmsman-specific helpers, exposing the
+// active environment and merged state values to the document. It is reused
+// both for the top-level DSF and, through readValuesFile, for environment,
+// --state-values-file and sub-helmfile values files. Template errors are wrapped with the file name so they surface
+// with file/line numbers from the template engine.
+func renderTemplate(file string, data []byte, env *environment, values map[string]interface{}) ([]byte, error) {
+	tplCtx := templateContext{
+		Values: values,
+		Env:    environAsMap(),
+	}
+	tplCtx.Environment.Name = flags.environment
+	if env != nil {
+		tplCtx.Environment.Values = env.Defaults
+	}
+
+	funcs := sprig.TxtFuncMap()
+	funcs["requiredEnv"] = requiredEnvTemplateFunc
+	funcs["exec"] = execTemplateFunc
+	funcs["readFile"] = readFileTemplateFunc
+	funcs["readDir"] = readDirTemplateFunc
+	funcs["get"] = getTemplateFunc
+	funcs["fromYaml"] = fromYamlTemplateFunc
+	funcs["tpl"] = func(s string, ctx interface{}) (string, error) {
+		return renderTemplateString(file, s, ctx, funcs)
+	}
+
+	tpl, err := template.New(file).Funcs(funcs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, tplCtx); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return []byte(buf.String()), nil
+}
+
+// templateContext is the data made available to a DSF while it is being
+// rendered.
+type templateContext struct {
+	Environment struct {
+		Name   string
+		Values map[string]interface{}
+	}
+	Values map[string]interface{}
+	Env    map[string]string
+}
+
+// renderTemplateString renders an arbitrary string (used by the `tpl`
+// template function) with the same function set as the top-level document.
+func renderTemplateString(file, s string, ctx interface{}, funcs template.FuncMap) (string, error) {
+	tpl, err := template.New(file + ":tpl").Funcs(funcs).Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, ctx); err != nil {
+		return "", fmt.Errorf("%s: %w", file, err)
+	}
+	return buf.String(), nil
+}
+
+// environAsMap returns os.Environ() as a lookup map for the `.Env` template
+// context.
+func environAsMap() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		}
+	}
+	return env
+}
+
+// requiredEnvTemplateFunc is the `requiredEnv` template helper: it returns
+// the named env var's value, or errors out the render if it is unset/empty.
+func requiredEnvTemplateFunc(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return "", fmt.Errorf("required env var [ %s ] is not set", name)
+	}
+	return v, nil
+}
+
+// execTemplateFunc is the `exec` template helper: it runs a command and
+// returns its trimmed stdout.
+func execTemplateFunc(command string, args ...string) (string, error) {
+	out, err := exec.Command(command, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("exec [ %s %s ] failed: %w", command, strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// readFileTemplateFunc is the `readFile` template helper.
+func readFileTemplateFunc(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// readDirTemplateFunc is the `readDir` template helper: it returns the
+// names of the entries in a directory.
+func readDirTemplateFunc(path string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
+
+// getTemplateFunc is the `get` template helper: it looks up a dotted path
+// (e.g. "a.b.c") in a map, returning def if any part of the path is absent.
+func getTemplateFunc(m map[string]interface{}, path string, def interface{}) interface{} {
+	cur := interface{}(m)
+	for _, p := range strings.Split(path, ".") {
+		asMap, ok := cur.(map[string]interface{})
+		if !ok {
+			return def
+		}
+		v, ok := asMap[p]
+		if !ok {
+			return def
+		}
+		cur = v
+	}
+	return cur
+}
+
+// fromYamlTemplateFunc is the `fromYaml` template helper: it unmarshals a
+// YAML string into a generic map for use elsewhere in the template.
+func fromYamlTemplateFunc(s string) (map[string]interface{}, error) {
+	out := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(s), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// errUnknownFieldPath is returned internally by applyFieldPath when a
+// --state-values-set path does not correspond to an actual DSF field; it is
+// not an error condition, since most paths only target the generic values
+// bag rather than a concrete struct field.
+var errUnknownFieldPath = errors.New("unknown state field path")
+
+// applyStateValueOverrides merges --state-values-file and --state-values-set
+// overrides into s.Values (files first, then -set, with later entries
+// winning), and additionally applies any --state-values-set entry that
+// happens to address a concrete DSF field (e.g. "settings.kubeContext").
+// Called by fromFile once the DSF is loaded, before validate().
+func (s *state) applyStateValueOverrides() error {
+	merged := map[string]interface{}{}
+	mergeValues(merged, s.Values)
+
+	overrides, err := computeStateValueOverrides()
+	if err != nil {
+		return err
+	}
+	mergeValues(merged, overrides)
+
+	for _, spec := range flags.stateValuesSet {
+		for _, pair := range strings.Split(spec, ",") {
+			if pair == "" {
+				continue
+			}
+			kv := strings.SplitN(pair, "=", 2)
+			key, raw := kv[0], kv[1]
+
+			if err := applyFieldPath(s, key, raw); err != nil && !errors.Is(err, errUnknownFieldPath) {
+				return fmt.Errorf("--state-values-set [ %s ]: %w", pair, err)
+			}
+		}
+	}
+
+	s.Values = merged
+	return nil
+}
+
+// computeStateValueOverrides parses --state-values-file and
+// --state-values-set into a merged values map on their own, independent of
+// any concrete state. It is used both to seed the .Values template context
+// before the root DSF is parsed (see state.fromFile), and by
+// applyStateValueOverrides to fold the same overrides into the final
+// parsed state's Values.
+func computeStateValueOverrides() (map[string]interface{}, error) {
+	merged := map[string]interface{}{}
+
+	for _, f := range flags.stateValuesFile {
+		fileValues, err := readValuesFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("--state-values-file [ %s ]: %w", f, err)
+		}
+		mergeValues(merged, fileValues)
+	}
+
+	for _, spec := range flags.stateValuesSet {
+		for _, pair := range strings.Split(spec, ",") {
+			if pair == "" {
+				continue
+			}
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid --state-values-set entry [ %s ], expected key=value", pair)
+			}
+			setNestedValue(merged, kv[0], inferValueType(kv[1]))
+		}
+	}
+
+	return merged, nil
+}
+
+// setNestedValue sets dotted path key (e.g. "a.b.c") to value within m,
+// creating missing intermediate maps and merging into existing ones rather
+// than clobbering sibling keys -- so setting "a.b.c=x" then "a.b.d=y" yields
+// {a:{b:{c:x,d:y}}}, not a clobbered "b".
+func setNestedValue(m map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	cur := m
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := cur[p].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			// yaml.v2 decodes nested maps as map[interface{}]interface{};
+			// carry their keys over instead of clobbering them
+			if old, isMap := cur[p].(map[interface{}]interface{}); isMap {
+				for k, v := range old {
+					next[fmt.Sprint(k)] = v
+				}
+			}
+			cur[p] = next
+		}
+		cur = next
+	}
+	cur[parts[len(parts)-1]] = value
+}
+
+// inferValueType converts a raw --state-values-set value into a bool/int64
+// when it unambiguously looks like one, and leaves it as a string otherwise.
+func inferValueType(raw string) interface{} {
+	if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return i
+	}
+	if b, err := strconv.ParseBool(raw); err == nil {
+		return b
+	}
+	return raw
+}
+
+// applyFieldPath walks s via reflection, following a dotted path of yaml
+// tag names (e.g. "settings.kubeContext"), and sets the final field to the
+// given raw value. It returns errUnknownFieldPath when the path does not
+// resolve to an actual field, which callers treat as a no-op.
+func applyFieldPath(s *state, path string, raw string) error {
+	v := reflect.ValueOf(s).Elem()
+	parts := strings.Split(path, ".")
+	for i, p := range parts {
+		v = fieldByYAMLTag(v, p)
+		if !v.IsValid() {
+			return errUnknownFieldPath
+		}
+		if i < len(parts)-1 {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return errUnknownFieldPath
+				}
+				v = v.Elem()
+			}
+			if v.Kind() != reflect.Struct {
+				return errUnknownFieldPath
+			}
+		}
+	}
+	return setReflectValue(v, raw)
+}
+
+// fieldByYAMLTag returns the struct field of v whose `yaml` tag matches
+// tag, or the zero Value if v is not a struct or no field matches.
+func fieldByYAMLTag(v reflect.Value, tag string) reflect.Value {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		yamlTag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if yamlTag == tag {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// setReflectValue sets v to raw, converted to v's underlying kind.
+func setReflectValue(v reflect.Value, raw string) error {
+	if !v.CanSet() {
+		return errUnknownFieldPath
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	default:
+		return fmt.Errorf("unsupported field kind [ %s ] for --state-values-set", v.Kind())
+	}
+	return nil
+}
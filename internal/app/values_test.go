@@ -0,0 +1,28 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetNestedValuePreservesSiblingKeys(t *testing.T) {
+	m := map[string]interface{}{}
+	setNestedValue(m, "a.b.c", "x")
+	setNestedValue(m, "a.b.d", "y")
+
+	want := map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": "x", "d": "y"}},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("setNestedValue() = %#v, want %#v", m, want)
+	}
+}
+
+func TestSetNestedValueTopLevel(t *testing.T) {
+	m := map[string]interface{}{}
+	setNestedValue(m, "name", "demo")
+
+	if m["name"] != "demo" {
+		t.Errorf("setNestedValue() = %#v, want name=demo", m)
+	}
+}